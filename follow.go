@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ORCID/log-to-gor/pkg/logfmt"
+)
+
+// followPollInterval is how often a follow-mode reader checks for new
+// lines (or file rotation) once it has caught up to EOF.
+const followPollInterval = 500 * time.Millisecond
+
+// syncer is implemented by *os.File; we sync after every record in follow
+// mode so that a downstream `gor --input-file -` tailing the output sees
+// it promptly.
+type syncer interface {
+	Sync() error
+}
+
+// runFollow tails path like `tail -f`: it first converts the lines already
+// in the file, then each newly-appended line into a .gor record as it
+// arrives. It reopens the file when log rotation
+// is detected (the underlying inode changes out from under us) and returns
+// when ctx is canceled, e.g. by SIGINT/SIGTERM.
+func runFollow(ctx context.Context, path string, w io.Writer, p logfmt.LineParser, opts writerOptions) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	ino, err := inode(f)
+	if err != nil {
+		return 0, fmt.Errorf("stat %s: %w", path, err)
+	}
+
+	reader := bufio.NewReader(f)
+	processedCount := 0
+	var pending string
+
+	for {
+		select {
+		case <-ctx.Done():
+			return processedCount, nil
+		default:
+		}
+
+		chunk, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return processedCount, fmt.Errorf("reading %s: %w", path, err)
+		}
+		if errors.Is(err, io.EOF) {
+			// Partial line: ReadString already consumed these bytes from the
+			// file, so hold onto them and prepend the rest once it arrives.
+			pending += chunk
+		} else {
+			line := pending + chunk
+			pending = ""
+			n, writeErr := emitLine(line, w, p, opts)
+			if writeErr != nil {
+				return processedCount, writeErr
+			}
+			processedCount += n
+			if s, ok := w.(syncer); ok {
+				if syncErr := s.Sync(); syncErr != nil {
+					log.Printf("⚠️  failed to sync output: %v", syncErr)
+				}
+			}
+			continue
+		}
+
+		// Caught up to EOF: check for rotation, then wait for more data.
+		currentIno, statErr := pathInode(path)
+		if statErr == nil && currentIno != ino {
+			newFile, openErr := os.Open(path)
+			if openErr == nil {
+				f.Close()
+				f = newFile
+				ino = currentIno
+				reader = bufio.NewReader(f)
+				if pending != "" {
+					log.Printf("⚠️  discarding unterminated line left over from rotated-away %s: %q", path, pending)
+					pending = ""
+				}
+				continue
+			}
+			log.Printf("⚠️  detected rotation of %s but failed to reopen: %v", path, openErr)
+		}
+
+		select {
+		case <-ctx.Done():
+			return processedCount, nil
+		case <-time.After(followPollInterval):
+		}
+	}
+}
+
+// emitLine parses and writes a single trimmed line, returning 1 if a
+// record was emitted and 0 if the parser skipped or buffered it.
+func emitLine(rawLine string, w io.Writer, p logfmt.LineParser, opts writerOptions) (int, error) {
+	entry, ok := parseLine(rawLine, p)
+	if !ok {
+		return 0, nil
+	}
+	return writeEntry(entry, w, opts)
+}
+
+// parseLine trims rawLine and hands it to p, logging and reporting ok=false
+// for blank lines, parser errors, and parser-buffered (incomplete) lines.
+func parseLine(rawLine string, p logfmt.LineParser) (logfmt.Entry, bool) {
+	line := trimNewline(rawLine)
+	if line == "" {
+		return logfmt.Entry{}, false
+	}
+	entry, ok, err := p.ParseLine(line)
+	if err != nil {
+		log.Printf("⚠️  Skipping malformed line: %s (%v)", line, err)
+		return logfmt.Entry{}, false
+	}
+	return entry, ok
+}
+
+// writeEntry applies the --since/--until window and --speedup/--relative-time
+// rewriting to entry, then emits it as a .gor record. It returns 1 if a
+// record was written and 0 if the window dropped it.
+func writeEntry(entry logfmt.Entry, w io.Writer, opts writerOptions) (int, error) {
+	if !opts.Window.Contains(entry.Timestamp) {
+		return 0, nil
+	}
+
+	reqID, err := generateRequestID()
+	if err != nil {
+		log.Printf("⚠️  Skipping line due to ID generation error: %v", err)
+		return 0, nil
+	}
+
+	timestamp := entry.Timestamp
+	if opts.Rewriter != nil {
+		timestamp = opts.Rewriter.Rewrite(timestamp)
+	}
+
+	var latencyNs int64
+	if opts.EmitResponses {
+		latencyNs = entry.Latency.Nanoseconds()
+	}
+
+	message := buildHTTPMessage(entry, opts)
+	if _, err := fmt.Fprintf(w, "%s %s %d %d\n", "1", reqID, timestamp.UnixNano(), latencyNs); err != nil {
+		return 0, fmt.Errorf("failed to write header: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return 0, fmt.Errorf("failed to write request message: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "\n%s\n", gorPayloadDelimiter); err != nil {
+		return 0, fmt.Errorf("failed to write delimiter: %w", err)
+	}
+
+	if opts.EmitResponses && entry.Status != 0 {
+		responseTimestamp := entry.Timestamp.Add(entry.Latency)
+		if opts.Rewriter != nil {
+			responseTimestamp = opts.Rewriter.Rewrite(responseTimestamp)
+		}
+		response := buildHTTPResponse(entry)
+		if _, err := fmt.Fprintf(w, "%s %s %d %d\n", "2", reqID, responseTimestamp.UnixNano(), latencyNs); err != nil {
+			return 1, fmt.Errorf("failed to write response header: %w", err)
+		}
+		if _, err := w.Write(response); err != nil {
+			return 1, fmt.Errorf("failed to write response message: %w", err)
+		}
+		if _, err := fmt.Fprintf(w, "\n%s\n", gorPayloadDelimiter); err != nil {
+			return 1, fmt.Errorf("failed to write delimiter: %w", err)
+		}
+	}
+
+	return 1, nil
+}
+
+// trimNewline strips a trailing \n and \r\n, leaving partial (unterminated)
+// lines untouched so the caller can tell them apart.
+func trimNewline(s string) string {
+	if n := len(s); n > 0 && s[n-1] == '\n' {
+		s = s[:n-1]
+	}
+	if n := len(s); n > 0 && s[n-1] == '\r' {
+		s = s[:n-1]
+	}
+	return s
+}
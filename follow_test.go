@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ORCID/log-to-gor/pkg/logfmt"
+)
+
+func apacheLine(url string) string {
+	return `127.0.0.1 - - [10/Oct/2023:13:00:00 -0700] "GET ` + url + ` HTTP/1.1" 200 1 "-" "ua"` + "\n"
+}
+
+func TestRunFollow_ProcessesExistingLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	content := apacheLine("/a") + apacheLine("/b")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	var buf bytes.Buffer
+	count, err := runFollow(ctx, path, &buf, logfmt.NewApacheCombinedParser(), writerOptions{})
+	if err != nil {
+		t.Fatalf("runFollow: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("count = %d, want 2", count)
+	}
+	if !strings.Contains(buf.String(), "/a ") || !strings.Contains(buf.String(), "/b ") {
+		t.Errorf("output missing expected URLs:\n%s", buf.String())
+	}
+}
+
+// TestRunFollow_RotationDiscardsStalePartialLine pins the fix for a bug
+// where an unterminated line buffered from the file being rotated away
+// stayed around and got glued onto the rotated-in file's first line.
+func TestRunFollow_RotationDiscardsStalePartialLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	partial := `127.0.0.1 - - [10/Oct/2023:13:00:00 -0700] "GET /old`
+	if err := os.WriteFile(path, []byte(partial), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var buf bytes.Buffer
+	done := make(chan struct{})
+	var count int
+	var runErr error
+	go func() {
+		count, runErr = runFollow(ctx, path, &buf, logfmt.NewApacheCombinedParser(), writerOptions{})
+		close(done)
+	}()
+
+	// Give runFollow time to read the partial line and buffer it as pending.
+	time.Sleep(100 * time.Millisecond)
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(apacheLine("/new")), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	// Rotation is only detected on the next poll tick.
+	time.Sleep(followPollInterval + 200*time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("runFollow did not return after cancel")
+	}
+	if runErr != nil {
+		t.Fatalf("runFollow: %v", runErr)
+	}
+
+	if count != 1 {
+		t.Errorf("count = %d, want 1 (only the rotated-in line, stale partial discarded)", count)
+	}
+	out := buf.String()
+	if strings.Contains(out, "/old") {
+		t.Errorf("output retained the discarded partial line:\n%s", out)
+	}
+	if !strings.Contains(out, "/new ") {
+		t.Errorf("output missing the rotated-in line:\n%s", out)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/ORCID/log-to-gor/pkg/logfmt"
+)
+
+// writerOptions controls how parsed entries are rendered into HTTP
+// messages for the .gor payload.
+type writerOptions struct {
+	// DefaultHost is used for the Host header when the log entry did not
+	// capture one (set via --default-host).
+	DefaultHost string
+	// SynthesizeHeaders adds plausible defaults (Accept, X-Forwarded-For)
+	// that the original request almost certainly sent but the log format
+	// does not record, so replayed traffic looks realistic.
+	SynthesizeHeaders bool
+	// Window drops entries whose timestamp falls outside it (--since/--until).
+	Window timeWindow
+	// Rewriter rescales/rebases emitted timestamps (--speedup/--relative-time/
+	// --start-at). Nil means emit timestamps unchanged.
+	Rewriter *timestampRewriter
+	// EmitResponses adds a synthesized type-2 (original response) record
+	// after each request and populates the request's latency field, for
+	// entries where the source format captured a status code (--emit-responses).
+	EmitResponses bool
+}
+
+// buildHTTPMessage renders entry as a full HTTP/1.1 request message:
+// request line, headers (including a synthesized Content-Length when a
+// body was recovered), a blank line, and the body bytes.
+func buildHTTPMessage(entry logfmt.Entry, opts writerOptions) []byte {
+	var buf bytes.Buffer
+
+	proto := entry.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	fmt.Fprintf(&buf, "%s %s %s\r\n", entry.Method, entry.URL, proto)
+
+	host := entry.Headers["Host"]
+	if host == "" {
+		host = opts.DefaultHost
+	}
+	if host != "" {
+		fmt.Fprintf(&buf, "Host: %s\r\n", host)
+	}
+
+	names := make([]string, 0, len(entry.Headers))
+	for name := range entry.Headers {
+		if name == "Host" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, entry.Headers[name])
+	}
+
+	if opts.SynthesizeHeaders {
+		if _, ok := entry.Headers["Accept"]; !ok {
+			buf.WriteString("Accept: */*\r\n")
+		}
+		if entry.RemoteAddr != "" {
+			if _, ok := entry.Headers["X-Forwarded-For"]; !ok {
+				fmt.Fprintf(&buf, "X-Forwarded-For: %s\r\n", entry.RemoteAddr)
+			}
+		}
+	}
+
+	if len(entry.Body) > 0 {
+		fmt.Fprintf(&buf, "Content-Length: %d\r\n", len(entry.Body))
+	}
+
+	buf.WriteString("\r\n")
+	buf.Write(entry.Body)
+	return buf.Bytes()
+}
+
+// buildHTTPResponse renders entry's recovered status/size as a synthesized
+// HTTP/1.1 response message, for the type-2 record emitted by
+// --emit-responses. The original response body isn't present in the
+// access log, only its size, so the body is omitted but Content-Length
+// still reflects it. entry.Headers holds recovered *request* headers, so
+// it is never consulted here: the log formats this tool supports don't
+// capture the response's actual Content-Type, so a generic placeholder is
+// used instead of guessing from the request.
+func buildHTTPResponse(entry logfmt.Entry) []byte {
+	var buf bytes.Buffer
+
+	reason := http.StatusText(entry.Status)
+	fmt.Fprintf(&buf, "HTTP/1.1 %d %s\r\n", entry.Status, reason)
+
+	fmt.Fprintf(&buf, "Content-Type: %s\r\n", "text/plain; charset=utf-8")
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n", entry.ResponseSize)
+
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ORCID/log-to-gor/pkg/logfmt"
+)
+
+func TestBuildHTTPMessage(t *testing.T) {
+	entry := logfmt.Entry{
+		Method:     "POST",
+		URL:        "/api/foo",
+		Proto:      "HTTP/1.1",
+		RemoteAddr: "127.0.0.1",
+		Headers:    map[string]string{"Host": "example.com", "Content-Type": "application/json"},
+		Body:       []byte(`{"a":1}`),
+	}
+
+	got := string(buildHTTPMessage(entry, writerOptions{}))
+
+	want := "POST /api/foo HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"Content-Type: application/json\r\n" +
+		"Content-Length: 7\r\n" +
+		"\r\n" +
+		`{"a":1}`
+	if got != want {
+		t.Errorf("buildHTTPMessage =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestBuildHTTPMessage_DefaultHostAndEmptyProto(t *testing.T) {
+	entry := logfmt.Entry{Method: "GET", URL: "/", Headers: map[string]string{}}
+
+	got := string(buildHTTPMessage(entry, writerOptions{DefaultHost: "fallback.example"}))
+
+	want := "GET / HTTP/1.1\r\nHost: fallback.example\r\n\r\n"
+	if got != want {
+		t.Errorf("buildHTTPMessage =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestBuildHTTPMessage_SynthesizeHeaders(t *testing.T) {
+	entry := logfmt.Entry{Method: "GET", URL: "/", RemoteAddr: "10.0.0.1", Headers: map[string]string{}}
+
+	got := string(buildHTTPMessage(entry, writerOptions{SynthesizeHeaders: true}))
+
+	if !strings.Contains(got, "Accept: */*\r\n") {
+		t.Errorf("buildHTTPMessage did not synthesize Accept header:\n%q", got)
+	}
+	if !strings.Contains(got, "X-Forwarded-For: 10.0.0.1\r\n") {
+		t.Errorf("buildHTTPMessage did not synthesize X-Forwarded-For header:\n%q", got)
+	}
+}
+
+func TestBuildHTTPMessage_SynthesizeHeadersDoesNotOverride(t *testing.T) {
+	entry := logfmt.Entry{Method: "GET", URL: "/", Headers: map[string]string{"Accept": "text/html"}}
+
+	got := string(buildHTTPMessage(entry, writerOptions{SynthesizeHeaders: true}))
+
+	if strings.Count(got, "Accept:") != 1 {
+		t.Errorf("buildHTTPMessage should not duplicate an already-present Accept header:\n%q", got)
+	}
+	if !strings.Contains(got, "Accept: text/html\r\n") {
+		t.Errorf("buildHTTPMessage overrode the recovered Accept header:\n%q", got)
+	}
+}
+
+func TestBuildHTTPResponse(t *testing.T) {
+	entry := logfmt.Entry{
+		Status:       404,
+		ResponseSize: 42,
+		// Headers only ever holds recovered *request* headers; it must not
+		// leak into the synthesized response's Content-Type.
+		Headers: map[string]string{"Content-Type": "application/json"},
+	}
+
+	got := string(buildHTTPResponse(entry))
+
+	want := "HTTP/1.1 404 Not Found\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Length: 42\r\n" +
+		"\r\n"
+	if got != want {
+		t.Errorf("buildHTTPResponse =\n%q\nwant\n%q", got, want)
+	}
+}
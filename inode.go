@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// inode returns the filesystem inode number backing f, used by --follow to
+// record the inode of the file it currently has open.
+func inode(f *os.File) (uint64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return inodeFromInfo(info)
+}
+
+// pathInode returns the inode number of the file currently at path, used by
+// --follow to detect log rotation (the path is replaced by a new file with
+// a new inode, e.g. via logrotate's create mode or kubelet's rotation).
+// Truncation in place, as with logrotate's copytruncate, keeps the inode and
+// is not detected this way. It must stat the path rather than an already-open file
+// descriptor: an open fd keeps referring to the rotated-away file's inode
+// even after the path is replaced.
+func pathInode(path string) (uint64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return inodeFromInfo(info)
+}
+
+func inodeFromInfo(info os.FileInfo) (uint64, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("inode: unsupported platform")
+	}
+	return stat.Ino, nil
+}
@@ -5,55 +5,148 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"regexp"
+	"os/signal"
+	"runtime"
+	"syscall"
 	"time"
 
-	parser "github.com/nekrassov01/access-log-parser"
+	"github.com/ORCID/log-to-gor/pkg/logfmt"
 )
 
 // The delimiter used by goreplay to separate payloads.
 const gorPayloadDelimiter = "🐵🙈🙉"
 
-// CombinedLogFormat is the standard format string for Apache Combined Log files.
-const CombinedLogFormat = `%h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-Agent}i"`
-
 func main() {
-	// 1. Check for command-line arguments
-	if len(os.Args) != 3 {
-		fmt.Println("Usage: log-to-gor <input_logfile> <output_gorfile>")
-		fmt.Println("Example: ./log-to-gor access.log requests.gor")
-		os.Exit(1)
+	format := flag.String("format", "apache-combined",
+		"input log format: apache-clf, apache-combined, nginx, json, cri")
+	formatRegex := flag.String("format-regex", "",
+		"regular expression used when --format is not recognized; must define named groups method and uri (proto, time, host, referer, useragent are used when present)")
+	formatRegexTimeLayout := flag.String("format-regex-time-layout", "",
+		"Go reference layout for the \"time\" named group in --format-regex (defaults to the Apache layout)")
+	nginxTemplate := flag.String("nginx-template", "",
+		"nginx log_format directive to parse, required when --format=nginx")
+	criInnerFormat := flag.String("cri-inner-format", "apache-combined",
+		"format of the message framed by CRI lines, used when --format=cri")
+	fieldMethod := flag.String("field-method", "", "JSON field holding the HTTP method, used when --format=json")
+	fieldURI := flag.String("field-uri", "", "JSON field holding the request URI (or full request line), used when --format=json")
+	fieldTime := flag.String("field-time", "", "JSON field holding the request timestamp, used when --format=json")
+	fieldRemoteAddr := flag.String("field-remote-addr", "", "JSON field holding the client address, used when --format=json")
+	fieldHTTPHost := flag.String("field-http-host", "", "JSON field holding the Host header, used when --format=json")
+	fieldBody := flag.String("field-body", "", "JSON field holding the request body, used when --format=json")
+	fieldContentType := flag.String("field-content-type", "", "JSON field holding the request Content-Type header, used when --format=json")
+	fieldCookie := flag.String("field-cookie", "", "JSON field holding the request Cookie header, used when --format=json")
+	defaultHost := flag.String("default-host", "", "Host header to emit when the log entry does not capture one")
+	synthesizeHeaders := flag.Bool("synthesize-headers", false, "add plausible default headers (Accept, X-Forwarded-For) missing from the source log")
+	follow := flag.Bool("follow", false, "tail the input file like `tail -f`, emitting records as new lines arrive (requires a real input file, not -)")
+	since := flag.String("since", "", "drop entries timestamped before this time (RFC3339 or 2006-01-02T15:04:05)")
+	until := flag.String("until", "", "drop entries timestamped after this time (RFC3339 or 2006-01-02T15:04:05)")
+	speedup := flag.Float64("speedup", 0, "divide inter-request gaps by this factor when rewriting emitted timestamps")
+	relativeTime := flag.Bool("relative-time", false, "rebase the first emitted timestamp to time.Now() (or --start-at)")
+	startAt := flag.String("start-at", "", "rebase the first emitted timestamp to this time (implies --relative-time); RFC3339 or 2006-01-02T15:04:05")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of parser worker goroutines (ignored in --follow mode, or set to 1 to disable pooling)")
+	buffer := flag.Int("buffer", 1024, "channel buffer size between the reader, parser workers, and writer")
+	emitResponses := flag.Bool("emit-responses", false, "emit a type-2 response record after each request and populate its latency, when the source format captured a status code")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: log-to-gor [flags] <input_logfile|-> <output_gorfile|->")
+		fmt.Fprintln(os.Stderr, "Example: ./log-to-gor access.log requests.gor")
+		fmt.Fprintln(os.Stderr, "Example: kubectl logs -f pod | log-to-gor - - | gor --input-file -")
+		flag.PrintDefaults()
 	}
+	flag.Parse()
 
-	inputFile := os.Args[1]
-	outputFile := os.Args[2]
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	inputFile := flag.Arg(0)
+	outputFile := flag.Arg(1)
 
-	log.Printf("Starting conversion from %s to %s", inputFile, outputFile)
+	parserInstance, err := buildParser(*format, *formatRegex, *formatRegexTimeLayout, *nginxTemplate, *criInnerFormat,
+		fieldMap(*fieldMethod, *fieldURI, *fieldTime, *fieldRemoteAddr, *fieldHTTPHost, *fieldBody, *fieldContentType, *fieldCookie))
+	if err != nil {
+		log.Fatalf("Error configuring parser: %v", err)
+	}
 
-	// 2. Open the input log file for reading
-	in, err := os.Open(inputFile)
+	window, err := buildTimeWindow(*since, *until)
 	if err != nil {
-		log.Fatalf("Error opening input file %s: %v", inputFile, err)
+		log.Fatalf("Error parsing --since/--until: %v", err)
+	}
+	rewriter, err := buildRewriter(*speedup, *relativeTime, *startAt)
+	if err != nil {
+		log.Fatalf("Error parsing --start-at: %v", err)
+	}
+
+	opts := writerOptions{
+		DefaultHost:       *defaultHost,
+		SynthesizeHeaders: *synthesizeHeaders,
+		Window:            window,
+		Rewriter:          rewriter,
+		EmitResponses:     *emitResponses,
 	}
-	defer in.Close()
 
-	// 3. Create the output .gor file for writing
-	out, err := os.Create(outputFile)
+	out, err := openOutput(outputFile)
 	if err != nil {
-		log.Fatalf("Error creating output file %s: %v", outputFile, err)
+		log.Fatalf("Error opening output %s: %v", outputFile, err)
 	}
 	defer out.Close()
 
-	// 4. Initialize the log parser for Apache Combined Log Format
-	ctx := context.Background()
-	parserInstance := parser.NewApacheCLFRegexParser(ctx, io.Discard, parser.Option{})
+	if *follow {
+		if inputFile == "-" {
+			log.Fatalf("--follow requires a real input file, not -")
+		}
+		log.Printf("Following %s, writing .gor records to %s", inputFile, outputFile)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		count, err := runFollow(ctx, inputFile, out, parserInstance, opts)
+		if err != nil {
+			log.Fatalf("Error during follow: %v", err)
+		}
+		log.Printf("✅ Stopped after converting %d log entries.", count)
+		return
+	}
+
+	log.Printf("Starting conversion from %s to %s", inputFile, outputFile)
 
-	// 5. Process the files line by line
-	count, err := processLogs(in, out, parserInstance)
+	in, err := openInput(inputFile)
+	if err != nil {
+		log.Fatalf("Error opening input %s: %v", inputFile, err)
+	}
+	defer in.Close()
+
+	if f, ok := in.(*os.File); ok && !window.Since.IsZero() {
+		if parserInstance.Name() == "cri" {
+			log.Printf("⚠️  --format=cri reassembles lines across calls and cannot be scanned backward; ignoring the --since fast-path")
+		} else {
+			// The fast-path scans backward, out of line order, so it must
+			// not share parserInstance with the forward pass below: a
+			// reused instance's internal state (e.g. a stateful parser's
+			// buffered partial lines) would be left corrupted by lines
+			// arriving out of sequence. A scratch instance built from the
+			// same flags is parser-equivalent but disposable.
+			scratchParser, err := buildParser(*format, *formatRegex, *formatRegexTimeLayout, *nginxTemplate, *criInnerFormat,
+				fieldMap(*fieldMethod, *fieldURI, *fieldTime, *fieldRemoteAddr, *fieldHTTPHost, *fieldBody, *fieldContentType, *fieldCookie))
+			if err != nil {
+				log.Fatalf("Error configuring parser: %v", err)
+			}
+			offset, err := seekSinceOffset(f, scratchParser, window.Since)
+			if err != nil {
+				log.Printf("⚠️  --since fast-path failed, scanning from the start: %v", err)
+				offset = 0
+			}
+			if _, err := f.Seek(offset, io.SeekStart); err != nil {
+				log.Fatalf("Error seeking %s: %v", inputFile, err)
+			}
+		}
+	}
+
+	count, err := processLogsParallel(in, out, parserInstance, opts, *workers, *buffer)
 	if err != nil {
 		log.Fatalf("Error during processing: %v", err)
 	}
@@ -62,67 +155,121 @@ func main() {
 	log.Printf("Output saved to %s", outputFile)
 }
 
-// processLogs reads from the reader, parses logs, and writes to the writer in .gor format.
-func processLogs(r io.Reader, w io.Writer, p *parser.RegexParser) (int, error) {
-	scanner := bufio.NewScanner(r)
-	processedCount := 0
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
-		// Parse the log line
-		entry, err := p.ParseString(line)
-		if err != nil || len(entry.Errors) > 0 || entry.Matched == 0 {
-			log.Printf("⚠️  Skipping malformed line: %s (%v)", line, err)
-			continue
-		}
-		// Extract timestamp and request line
-		// Find the request line in the log
-		// For Combined Log Format, request line is in quotes after the date
-		// We'll use regex to extract it
-		var requestLine string
-		var timestamp int64
-		// Try to extract request line and timestamp from the parsed result
-		// The parser does not expose fields directly, so we use regex fallback
-		re := regexp.MustCompile(`"(GET|POST|PUT|DELETE|HEAD|OPTIONS|PATCH) ([^ ]+) ([^"]+)"`)
-		matches := re.FindStringSubmatch(line)
-		if len(matches) == 4 {
-			requestLine = fmt.Sprintf("%s %s %s", matches[1], matches[2], matches[3])
-		} else {
-			continue
-		}
-		// Extract timestamp from the log line
-		timeRe := regexp.MustCompile(`\[(\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2}) [^\]]+\]`)
-		timeMatch := timeRe.FindStringSubmatch(line)
-		if len(timeMatch) == 2 {
-			t, err := time.Parse("02/Jan/2006:15:04:05", timeMatch[1])
-			if err == nil {
-				timestamp = t.UnixNano()
-			}
+// openInput opens path for reading, treating "-" as stdin.
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// openOutput opens path for writing, treating "-" as stdout.
+func openOutput(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+// nopWriteCloser adapts os.Stdout (which must not be closed by us) to
+// io.WriteCloser while still supporting the Sync method follow mode uses
+// to flush records promptly.
+type nopWriteCloser struct {
+	*os.File
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// buildParser resolves the --format flag (and its associated options) into
+// a logfmt.LineParser, falling back to a user-supplied --format-regex for
+// formats logfmt does not recognize.
+func buildParser(format, formatRegex, formatRegexTimeLayout, nginxTemplate, criInnerFormat string, fields map[string]string) (logfmt.LineParser, error) {
+	p, err := logfmt.New(format, logfmt.Options{
+		NginxTemplate: nginxTemplate,
+		FieldMap:      fields,
+		InnerFormat:   criInnerFormat,
+	})
+	if err == nil {
+		return p, nil
+	}
+	if formatRegex == "" {
+		return nil, err
+	}
+	return logfmt.NewRegexParser(formatRegex, formatRegexTimeLayout)
+}
+
+// fieldMap collects the --field-* flags into the map logfmt.Options.FieldMap
+// expects, omitting any that were left at their zero value.
+func fieldMap(method, uri, timestamp, remoteAddr, httpHost, body, contentType, cookie string) map[string]string {
+	m := map[string]string{}
+	for k, v := range map[string]string{
+		"method":       method,
+		"uri":          uri,
+		"timestamp":    timestamp,
+		"remote_addr":  remoteAddr,
+		"http_host":    httpHost,
+		"body":         body,
+		"content_type": contentType,
+		"cookie":       cookie,
+	} {
+		if v != "" {
+			m[k] = v
 		}
-		// Generate request ID
-		reqID, err := generateRequestID()
+	}
+	return m
+}
+
+// buildTimeWindow parses the --since/--until flags, leaving either bound
+// zero (unbounded) when its flag was not set.
+func buildTimeWindow(since, until string) (timeWindow, error) {
+	var w timeWindow
+	if since != "" {
+		t, err := parseFlagTime(since)
 		if err != nil {
-			log.Printf("⚠️  Skipping line due to ID generation error: %v", err)
-			continue
+			return w, fmt.Errorf("--since: %w", err)
 		}
-		// Write .gor format
-		reqType := "1"
-		latency := 0
-		_, err = fmt.Fprintf(w, "%s %s %d %d\n", reqType, reqID, timestamp, latency)
+		w.Since = t
+	}
+	if until != "" {
+		t, err := parseFlagTime(until)
 		if err != nil {
-			return processedCount, fmt.Errorf("failed to write header: %w", err)
+			return w, fmt.Errorf("--until: %w", err)
 		}
-		_, err = fmt.Fprintf(w, "%s\r\n\r\n\n", requestLine)
+		w.Until = t
+	}
+	return w, nil
+}
+
+// buildRewriter builds the timestamp rewriter for --speedup/--relative-time/
+// --start-at, or nil if none of them were set.
+func buildRewriter(speedup float64, relativeTime bool, startAt string) (*timestampRewriter, error) {
+	var base time.Time
+	switch {
+	case startAt != "":
+		t, err := parseFlagTime(startAt)
 		if err != nil {
-			return processedCount, fmt.Errorf("failed to write request line: %w", err)
+			return nil, fmt.Errorf("--start-at: %w", err)
 		}
-		_, err = fmt.Fprintf(w, "%s\n", gorPayloadDelimiter)
+		base = t
+	case relativeTime:
+		base = time.Now()
+	}
+	if speedup <= 0 && base.IsZero() {
+		return nil, nil
+	}
+	return newTimestampRewriter(speedup, base), nil
+}
+
+// processLogs reads from the reader, parses logs, and writes to the writer in .gor format.
+func processLogs(r io.Reader, w io.Writer, p logfmt.LineParser, opts writerOptions) (int, error) {
+	scanner := bufio.NewScanner(r)
+	processedCount := 0
+	for scanner.Scan() {
+		n, err := emitLine(scanner.Text()+"\n", w, p, opts)
 		if err != nil {
-			return processedCount, fmt.Errorf("failed to write delimiter: %w", err)
+			return processedCount, err
 		}
-		processedCount++
+		processedCount += n
 	}
 	if err := scanner.Err(); err != nil {
 		return processedCount, fmt.Errorf("error reading input file: %w", err)
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/ORCID/log-to-gor/pkg/logfmt"
+)
+
+// parseJob is one scanned line tagged with its position in the input, so
+// the writer can restore order after parsing fans out across workers.
+type parseJob struct {
+	seq  int
+	line string
+}
+
+// parseResult is the outcome of parsing a parseJob.
+type parseResult struct {
+	seq   int
+	entry logfmt.Entry
+	ok    bool
+}
+
+// processLogsParallel is a worker-pool variant of processLogs: one reader
+// goroutine scans lines and dispatches them (tagged with a sequence number)
+// to workers parser goroutines, and a single writer goroutine reassembles
+// results in sequence order via a small heap before writing them out. Only
+// parsing is done concurrently; ID generation, --since/--until filtering and
+// --speedup/--relative-time rewriting stay on the writer goroutine, in
+// order, so output is byte-for-byte identical to processLogs regardless of
+// worker count, apart from the randomly generated request IDs.
+//
+// Stateful parsers (currently just "cri", which reassembles partial lines
+// across calls) cannot be parsed out of order, so workers is ignored for
+// them.
+func processLogsParallel(r io.Reader, w io.Writer, p logfmt.LineParser, opts writerOptions, workers, bufferSize int) (int, error) {
+	if workers <= 1 {
+		return processLogs(r, w, p, opts)
+	}
+	if p.Name() == "cri" {
+		log.Printf("⚠️  --format=cri reassembles lines across calls and cannot be parsed out of order; ignoring --workers=%d", workers)
+		return processLogs(r, w, p, opts)
+	}
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+
+	jobs := make(chan parseJob, bufferSize)
+	results := make(chan parseResult, bufferSize)
+
+	var scanErr error
+	go func() {
+		defer close(jobs)
+		scanner := bufio.NewScanner(r)
+		seq := 0
+		for scanner.Scan() {
+			jobs <- parseJob{seq: seq, line: scanner.Text() + "\n"}
+			seq++
+		}
+		scanErr = scanner.Err()
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				entry, ok := parseLine(job.line, p)
+				results <- parseResult{seq: job.seq, entry: entry, ok: ok}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	processedCount, err := reassembleAndWrite(results, w, opts)
+	if err != nil {
+		return processedCount, err
+	}
+	if scanErr != nil {
+		return processedCount, fmt.Errorf("error reading input file: %w", scanErr)
+	}
+	return processedCount, nil
+}
+
+// reassembleAndWrite drains results, which may arrive out of order, holding
+// each one in a min-heap keyed by sequence number until its turn comes up.
+func reassembleAndWrite(results <-chan parseResult, w io.Writer, opts writerOptions) (int, error) {
+	pending := &resultHeap{}
+	heap.Init(pending)
+	next := 0
+	processedCount := 0
+
+	for r := range results {
+		heap.Push(pending, r)
+		for pending.Len() > 0 && (*pending)[0].seq == next {
+			result := heap.Pop(pending).(parseResult)
+			next++
+			if !result.ok {
+				continue
+			}
+			n, err := writeEntry(result.entry, w, opts)
+			if err != nil {
+				return processedCount, err
+			}
+			processedCount += n
+		}
+	}
+	return processedCount, nil
+}
+
+// resultHeap orders parseResults by sequence number.
+type resultHeap []parseResult
+
+func (h resultHeap) Len() int            { return len(h) }
+func (h resultHeap) Less(i, j int) bool  { return h[i].seq < h[j].seq }
+func (h resultHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x interface{}) { *h = append(*h, x.(parseResult)) }
+func (h *resultHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ORCID/log-to-gor/pkg/logfmt"
+)
+
+// benchLogLines generates n Apache Combined Log Format lines for benchmarking.
+func benchLogLines(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "127.0.0.1 - - [10/Oct/2023:13:55:36 -0700] \"GET /api/resource/%d HTTP/1.1\" 200 123 \"-\" \"bench/1.0\"\n", i)
+	}
+	return b.String()
+}
+
+func benchmarkProcess(b *testing.B, workers int) {
+	input := benchLogLines(1_000_000)
+	opts := writerOptions{}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var out bytes.Buffer
+		p := logfmt.NewApacheCombinedParser()
+		var err error
+		if workers <= 1 {
+			_, err = processLogs(strings.NewReader(input), io.Writer(&out), p, opts)
+		} else {
+			_, err = processLogsParallel(strings.NewReader(input), io.Writer(&out), p, opts, workers, 1024)
+		}
+		if err != nil {
+			b.Fatalf("processing failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkProcessLogs_SingleThreaded measures the baseline, single-goroutine
+// parsing path (--workers=1) on a 1M-line CLF sample.
+func BenchmarkProcessLogs_SingleThreaded(b *testing.B) {
+	benchmarkProcess(b, 1)
+}
+
+// BenchmarkProcessLogs_Pooled measures the worker-pool path (--workers=8) on
+// the same 1M-line CLF sample.
+func BenchmarkProcessLogs_Pooled(b *testing.B) {
+	benchmarkProcess(b, 8)
+}
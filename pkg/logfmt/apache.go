@@ -0,0 +1,104 @@
+package logfmt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// apacheTimeLayout is the strftime-ish layout Apache uses for %t.
+const apacheTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// apacheCLFPattern matches the Common Log Format, plus an optional trailing
+// %D (request duration in microseconds), as in:
+// %h %l %u %t "%r" %>s %b %D
+var apacheCLFPattern = regexp.MustCompile(
+	`^(?P<host>\S+) (?P<ident>\S+) (?P<user>\S+) \[(?P<time>[^\]]+)\] ` +
+		`"(?P<method>\S+) (?P<uri>\S+) (?P<proto>[^"]+)" (?P<status>\d{3}) (?P<size>\S+)` +
+		`(?: (?P<duration_us>\d+))?`)
+
+// apacheCombinedPattern extends CLF with Referer and User-Agent, plus an
+// optional trailing %D:
+// %h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-Agent}i" %D
+var apacheCombinedPattern = regexp.MustCompile(
+	`^(?P<host>\S+) (?P<ident>\S+) (?P<user>\S+) \[(?P<time>[^\]]+)\] ` +
+		`"(?P<method>\S+) (?P<uri>\S+) (?P<proto>[^"]+)" (?P<status>\d{3}) (?P<size>\S+) ` +
+		`"(?P<referer>[^"]*)" "(?P<useragent>[^"]*)"(?: (?P<duration_us>\d+))?`)
+
+// apacheParser parses either Apache Common or Combined Log Format lines
+// using a fixed regular expression.
+type apacheParser struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// NewApacheCLFParser returns a LineParser for the Apache Common Log Format.
+func NewApacheCLFParser() LineParser {
+	return &apacheParser{name: "apache-clf", re: apacheCLFPattern}
+}
+
+// NewApacheCombinedParser returns a LineParser for the Apache Combined Log
+// Format (CLF plus Referer and User-Agent).
+func NewApacheCombinedParser() LineParser {
+	return &apacheParser{name: "apache-combined", re: apacheCombinedPattern}
+}
+
+func (p *apacheParser) Name() string { return p.name }
+
+func (p *apacheParser) ParseLine(line string) (Entry, bool, error) {
+	m := p.re.FindStringSubmatch(line)
+	if m == nil {
+		return Entry{}, false, fmt.Errorf("%s: line does not match expected format", p.name)
+	}
+	groups := namedGroups(p.re, m)
+
+	ts, err := time.Parse(apacheTimeLayout, groups["time"])
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("%s: parsing timestamp: %w", p.name, err)
+	}
+
+	headers := map[string]string{}
+	if referer, ok := groups["referer"]; ok && referer != "" && referer != "-" {
+		headers["Referer"] = referer
+	}
+	if ua, ok := groups["useragent"]; ok && ua != "" && ua != "-" {
+		headers["User-Agent"] = ua
+	}
+
+	entry := Entry{
+		Method:     groups["method"],
+		URL:        groups["uri"],
+		Proto:      groups["proto"],
+		Headers:    headers,
+		RemoteAddr: groups["host"],
+		Timestamp:  ts,
+	}
+	if status, err := strconv.Atoi(groups["status"]); err == nil {
+		entry.Status = status
+	}
+	if size := groups["size"]; size != "" && size != "-" {
+		if n, err := strconv.ParseInt(size, 10, 64); err == nil {
+			entry.ResponseSize = n
+		}
+	}
+	if us := groups["duration_us"]; us != "" {
+		if n, err := strconv.ParseInt(us, 10, 64); err == nil {
+			entry.Latency = time.Duration(n) * time.Microsecond
+		}
+	}
+	return entry, true, nil
+}
+
+// namedGroups builds a map of named capture group to matched text from a
+// FindStringSubmatch result.
+func namedGroups(re *regexp.Regexp, m []string) map[string]string {
+	groups := make(map[string]string, len(m))
+	for i, name := range re.SubexpNames() {
+		if i == 0 || name == "" {
+			continue
+		}
+		groups[name] = m[i]
+	}
+	return groups
+}
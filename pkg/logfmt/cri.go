@@ -0,0 +1,62 @@
+package logfmt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// criLinePattern matches the CRI log line framing used by kubelet/podman:
+// <RFC3339Nano time> <stream> <F|P> <msg>
+var criLinePattern = regexp.MustCompile(`^(\S+) (stdout|stderr) (F|P) (.*)$`)
+
+// criParser reassembles CRI-framed lines (which split long log lines across
+// multiple "P" partial records terminated by an "F" full record) and hands
+// the reconstructed message to an inner parser.
+type criParser struct {
+	inner  LineParser
+	buffer map[string]*strings.Builder
+}
+
+// NewCRIParser wraps inner, which parses the message once CRI framing has
+// been stripped and partial lines reassembled.
+func NewCRIParser(inner LineParser) LineParser {
+	return &criParser{inner: inner, buffer: map[string]*strings.Builder{}}
+}
+
+func (p *criParser) Name() string { return "cri" }
+
+func (p *criParser) ParseLine(line string) (Entry, bool, error) {
+	m := criLinePattern.FindStringSubmatch(line)
+	if m == nil {
+		return Entry{}, false, fmt.Errorf("cri: line does not match <time> <stream> <F|P> <msg> framing")
+	}
+	timestamp, stream, tag, msg := m[1], m[2], m[3], m[4]
+
+	b, buffering := p.buffer[stream]
+	if !buffering {
+		b = &strings.Builder{}
+		p.buffer[stream] = b
+	}
+	b.WriteString(msg)
+
+	if tag == "P" {
+		// Partial line: keep buffering until a terminating "F" arrives.
+		return Entry{}, false, nil
+	}
+
+	full := b.String()
+	delete(p.buffer, stream)
+
+	entry, ok, err := p.inner.ParseLine(full)
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("cri: %w", err)
+	}
+	if ok && entry.Timestamp.IsZero() {
+		if ts, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+			entry.Timestamp = ts
+		}
+	}
+	return entry, ok, nil
+}
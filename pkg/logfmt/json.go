@@ -0,0 +1,137 @@
+package logfmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultJSONFieldMap is used when the caller does not override a
+// particular logical field via --field-*.
+var defaultJSONFieldMap = map[string]string{
+	"method":       "method",
+	"uri":          "uri",
+	"timestamp":    "timestamp",
+	"remote_addr":  "remote_addr",
+	"http_host":    "http_host",
+	"body":         "request_body",
+	"status":       "status",
+	"latency":      "reqalldealtime",
+	"content_type": "",
+	"cookie":       "",
+}
+
+// jsonParser parses one JSON object per line, mapping arbitrary keys onto
+// Entry fields via a caller-supplied FieldMap (see --field-method etc.).
+type jsonParser struct {
+	fields map[string]string
+}
+
+// NewJSONParser returns a LineParser for JSON access logs. fields maps
+// logical names ("method", "uri", "timestamp", "remote_addr", "http_host",
+// "body", "content_type", "cookie", "status", "latency") to the JSON keys
+// that hold them; entries missing from fields
+// fall back to defaultJSONFieldMap.
+func NewJSONParser(fields map[string]string) LineParser {
+	merged := make(map[string]string, len(defaultJSONFieldMap))
+	for k, v := range defaultJSONFieldMap {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &jsonParser{fields: merged}
+}
+
+func (p *jsonParser) Name() string { return "json" }
+
+func (p *jsonParser) ParseLine(line string) (Entry, bool, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return Entry{}, false, fmt.Errorf("json: %w", err)
+	}
+
+	entry := Entry{
+		Proto:      "HTTP/1.1",
+		Method:     stringField(raw, p.fields["method"]),
+		RemoteAddr: stringField(raw, p.fields["remote_addr"]),
+		Headers:    map[string]string{},
+	}
+
+	uri := stringField(raw, p.fields["uri"])
+	if m, u, proto, ok := splitRequestLine(uri); ok {
+		entry.Method, entry.URL, entry.Proto = m, u, proto
+	} else {
+		entry.URL = uri
+	}
+
+	if host := stringField(raw, p.fields["http_host"]); host != "" {
+		entry.Headers["Host"] = host
+	}
+	if contentType := stringField(raw, p.fields["content_type"]); contentType != "" {
+		entry.Headers["Content-Type"] = contentType
+	}
+	if cookie := stringField(raw, p.fields["cookie"]); cookie != "" {
+		entry.Headers["Cookie"] = cookie
+	}
+	if body := stringField(raw, p.fields["body"]); body != "" {
+		entry.Body = []byte(body)
+	}
+
+	if rawTS := stringField(raw, p.fields["timestamp"]); rawTS != "" {
+		ts, err := parseJSONTimestamp(rawTS)
+		if err != nil {
+			return Entry{}, false, fmt.Errorf("json: parsing timestamp %q: %w", rawTS, err)
+		}
+		entry.Timestamp = ts
+	}
+	if status := stringField(raw, p.fields["status"]); status != "" {
+		if n, err := strconv.Atoi(status); err == nil {
+			entry.Status = n
+		}
+	}
+	if latency := stringField(raw, p.fields["latency"]); latency != "" {
+		if d, err := parseSeconds(latency); err == nil {
+			entry.Latency = d
+		}
+	}
+
+	return entry, true, nil
+}
+
+// stringField reads key from m, coercing numbers to their string form.
+// Missing or null values return "".
+func stringField(m map[string]interface{}, key string) string {
+	if key == "" {
+		return ""
+	}
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// splitRequestLine splits a combined "METHOD /uri HTTP/1.1" value, as
+// produced by e.g. nginx's $request when mapped into a JSON field.
+func splitRequestLine(s string) (method, uri, proto string, ok bool) {
+	parts := strings.Fields(s)
+	if len(parts) != 3 {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// parseJSONTimestamp accepts either RFC3339 or an nginx-style
+// "02/Jan/2006:15:04:05 -0700" timestamp.
+func parseJSONTimestamp(s string) (time.Time, error) {
+	if ts, err := time.Parse(time.RFC3339, s); err == nil {
+		return ts, nil
+	}
+	return time.Parse(apacheTimeLayout, s)
+}
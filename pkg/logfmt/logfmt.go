@@ -0,0 +1,87 @@
+// Package logfmt defines a pluggable line-parser abstraction for the access
+// log formats that log-to-gor can convert into goreplay .gor records.
+package logfmt
+
+import (
+	"fmt"
+	"time"
+)
+
+// Entry is the normalized representation of a single access-log request,
+// regardless of which on-disk format it was parsed from.
+type Entry struct {
+	Method     string
+	URL        string
+	Proto      string
+	Headers    map[string]string
+	Body       []byte
+	RemoteAddr string
+	Timestamp  time.Time
+
+	// Status, Latency and ResponseSize are populated when the source format
+	// captures them (Apache's %>s/%D, nginx's $status/$request_time, the
+	// JSON "status"/"reqalldealtime" fields). They are zero otherwise, and
+	// are only consumed by --emit-responses.
+	Status       int
+	Latency      time.Duration
+	ResponseSize int64
+}
+
+// LineParser turns raw log lines into Entry values. Implementations may be
+// stateless (one line in, one Entry out) or may buffer partial lines
+// internally, which is why ParseLine reports ok=false when it has consumed
+// a line but has nothing complete to emit yet.
+type LineParser interface {
+	// Name identifies the parser, e.g. for log messages.
+	Name() string
+	// ParseLine consumes one raw line of input. ok is false when the line
+	// was buffered rather than producing a complete Entry (used by parsers
+	// that reassemble multi-line records, such as CRI).
+	ParseLine(line string) (entry Entry, ok bool, err error)
+}
+
+// Options configures the parsers built by New. Only the fields relevant to
+// the requested format need to be set.
+type Options struct {
+	// NginxTemplate is the nginx log_format string (using $variable
+	// placeholders) used by the "nginx" format.
+	NginxTemplate string
+
+	// FieldMap maps logical Entry fields (method, uri, timestamp,
+	// remote_addr, http_host, body, content_type, cookie) to the JSON keys
+	// that hold them, used by the "json" format. Keys not present fall back
+	// to the JSON parser's defaults (defaultJSONFieldMap).
+	FieldMap map[string]string
+
+	// InnerFormat is the format of the message framed by CRI lines, used by
+	// the "cri" format. Defaults to "apache-combined".
+	InnerFormat string
+}
+
+// New constructs the LineParser registered under format. It returns an
+// error for unknown formats; callers should fall back to NewRegexParser
+// with a user-supplied template in that case.
+func New(format string, opts Options) (LineParser, error) {
+	switch format {
+	case "apache-clf":
+		return NewApacheCLFParser(), nil
+	case "apache-combined", "":
+		return NewApacheCombinedParser(), nil
+	case "nginx":
+		return NewNginxTemplateParser(opts.NginxTemplate)
+	case "json":
+		return NewJSONParser(opts.FieldMap), nil
+	case "cri":
+		inner := opts.InnerFormat
+		if inner == "" {
+			inner = "apache-combined"
+		}
+		innerParser, err := New(inner, opts)
+		if err != nil {
+			return nil, fmt.Errorf("cri: building inner parser %q: %w", inner, err)
+		}
+		return NewCRIParser(innerParser), nil
+	default:
+		return nil, fmt.Errorf("logfmt: unknown format %q", format)
+	}
+}
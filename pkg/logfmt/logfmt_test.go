@@ -0,0 +1,213 @@
+package logfmt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApacheCombinedParser_ParseLine(t *testing.T) {
+	p := NewApacheCombinedParser()
+	line := `127.0.0.1 - - [10/Oct/2023:13:55:36 -0700] "GET /api/foo HTTP/1.1" 200 123 "http://example.com/" "curl/7.68.0" 45000`
+
+	entry, ok, err := p.ParseLine(line)
+	if err != nil {
+		t.Fatalf("ParseLine returned error: %v", err)
+	}
+	if !ok {
+		t.Fatalf("ParseLine returned ok=false for a complete line")
+	}
+
+	if entry.Method != "GET" || entry.URL != "/api/foo" || entry.Proto != "HTTP/1.1" {
+		t.Errorf("request line = %q %q %q, want GET /api/foo HTTP/1.1", entry.Method, entry.URL, entry.Proto)
+	}
+	if entry.RemoteAddr != "127.0.0.1" {
+		t.Errorf("RemoteAddr = %q, want 127.0.0.1", entry.RemoteAddr)
+	}
+	if entry.Status != 200 {
+		t.Errorf("Status = %d, want 200", entry.Status)
+	}
+	if entry.ResponseSize != 123 {
+		t.Errorf("ResponseSize = %d, want 123", entry.ResponseSize)
+	}
+	if entry.Latency != 45000*time.Microsecond {
+		t.Errorf("Latency = %v, want 45ms", entry.Latency)
+	}
+	if entry.Headers["User-Agent"] != "curl/7.68.0" {
+		t.Errorf("User-Agent header = %q, want curl/7.68.0", entry.Headers["User-Agent"])
+	}
+	if entry.Headers["Referer"] != "http://example.com/" {
+		t.Errorf("Referer header = %q, want http://example.com/", entry.Headers["Referer"])
+	}
+
+	wantTime := time.Date(2023, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*60*60))
+	if !entry.Timestamp.Equal(wantTime) {
+		t.Errorf("Timestamp = %v, want %v", entry.Timestamp, wantTime)
+	}
+}
+
+func TestApacheCombinedParser_ParseLine_Malformed(t *testing.T) {
+	p := NewApacheCombinedParser()
+	if _, ok, err := p.ParseLine("not an apache log line"); err == nil || ok {
+		t.Fatalf("ParseLine(malformed) = ok=%v err=%v, want an error and ok=false", ok, err)
+	}
+}
+
+func TestNginxTemplateParser_ParseLine(t *testing.T) {
+	template := `$remote_addr [$time_local] "$request" $status $body_bytes_sent "$http_referer" "$http_user_agent" "$http_cookie" "$http_content_type"`
+	p, err := NewNginxTemplateParser(template)
+	if err != nil {
+		t.Fatalf("NewNginxTemplateParser: %v", err)
+	}
+
+	line := `10.0.0.1 [10/Oct/2023:13:55:36 +0000] "GET /api/foo HTTP/1.1" 200 123 "-" "curl/7.68.0" "session=abc123" "application/json"`
+	entry, ok, err := p.ParseLine(line)
+	if err != nil {
+		t.Fatalf("ParseLine returned error: %v", err)
+	}
+	if !ok {
+		t.Fatalf("ParseLine returned ok=false for a complete line")
+	}
+
+	if entry.Method != "GET" || entry.URL != "/api/foo" || entry.Proto != "HTTP/1.1" {
+		t.Errorf("request line = %q %q %q, want GET /api/foo HTTP/1.1", entry.Method, entry.URL, entry.Proto)
+	}
+	if entry.Status != 200 {
+		t.Errorf("Status = %d, want 200", entry.Status)
+	}
+	if entry.ResponseSize != 123 {
+		t.Errorf("ResponseSize = %d, want 123", entry.ResponseSize)
+	}
+	// $http_cookie and $http_content_type have no dedicated handling; they
+	// must still be recovered generically (any $http_* variable).
+	if entry.Headers["Cookie"] != "session=abc123" {
+		t.Errorf("Cookie header = %q, want session=abc123", entry.Headers["Cookie"])
+	}
+	if entry.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Content-Type header = %q, want application/json", entry.Headers["Content-Type"])
+	}
+	if entry.Headers["User-Agent"] != "curl/7.68.0" {
+		t.Errorf("User-Agent header = %q, want curl/7.68.0", entry.Headers["User-Agent"])
+	}
+	if _, ok := entry.Headers["Referer"]; ok {
+		t.Errorf("Referer header should be absent for a \"-\" value, got %q", entry.Headers["Referer"])
+	}
+}
+
+func TestJSONParser_ParseLine(t *testing.T) {
+	p := NewJSONParser(map[string]string{
+		"content_type": "ctype",
+		"cookie":       "ck",
+	})
+
+	line := `{"method":"POST","uri":"/api/bar","timestamp":"2023-10-10T13:55:37Z","remote_addr":"127.0.0.1","status":"201","reqalldealtime":"0.25","ctype":"application/json","ck":"session=xyz"}`
+	entry, ok, err := p.ParseLine(line)
+	if err != nil {
+		t.Fatalf("ParseLine returned error: %v", err)
+	}
+	if !ok {
+		t.Fatalf("ParseLine returned ok=false for a complete line")
+	}
+
+	if entry.Method != "POST" || entry.URL != "/api/bar" {
+		t.Errorf("method/uri = %q %q, want POST /api/bar", entry.Method, entry.URL)
+	}
+	if entry.RemoteAddr != "127.0.0.1" {
+		t.Errorf("RemoteAddr = %q, want 127.0.0.1", entry.RemoteAddr)
+	}
+	if entry.Status != 201 {
+		t.Errorf("Status = %d, want 201", entry.Status)
+	}
+	if entry.Latency != 250*time.Millisecond {
+		t.Errorf("Latency = %v, want 250ms", entry.Latency)
+	}
+	if entry.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Content-Type header = %q, want application/json", entry.Headers["Content-Type"])
+	}
+	if entry.Headers["Cookie"] != "session=xyz" {
+		t.Errorf("Cookie header = %q, want session=xyz", entry.Headers["Cookie"])
+	}
+	if !entry.Timestamp.Equal(time.Date(2023, 10, 10, 13, 55, 37, 0, time.UTC)) {
+		t.Errorf("Timestamp = %v, want 2023-10-10T13:55:37Z", entry.Timestamp)
+	}
+}
+
+func TestCRIParser_ReassemblesPartialLines(t *testing.T) {
+	inner := NewApacheCombinedParser()
+	p := NewCRIParser(inner)
+
+	full := `127.0.0.1 - - [10/Oct/2023:13:55:36 -0700] "GET /api/foo HTTP/1.1" 200 123 "-" "curl/7.68.0"`
+	part1, part2 := full[:30], full[30:]
+
+	_, ok, err := p.ParseLine("2023-10-10T20:55:36.000000000Z stdout P " + part1)
+	if err != nil {
+		t.Fatalf("ParseLine(partial) returned error: %v", err)
+	}
+	if ok {
+		t.Fatalf("ParseLine(partial) returned ok=true, want buffered (ok=false)")
+	}
+
+	entry, ok, err := p.ParseLine("2023-10-10T20:55:36.000000000Z stdout F " + part2)
+	if err != nil {
+		t.Fatalf("ParseLine(final) returned error: %v", err)
+	}
+	if !ok {
+		t.Fatalf("ParseLine(final) returned ok=false, want the reassembled entry")
+	}
+	if entry.Method != "GET" || entry.URL != "/api/foo" {
+		t.Errorf("reassembled request line = %q %q, want GET /api/foo", entry.Method, entry.URL)
+	}
+}
+
+func TestCRIParser_SeparatesStreams(t *testing.T) {
+	inner := NewApacheCombinedParser()
+	p := NewCRIParser(inner)
+
+	stdoutLine := `127.0.0.1 - - [10/Oct/2023:13:55:36 -0700] "GET /api/foo HTTP/1.1" 200 1 "-" "ua"`
+	stderrLine := `127.0.0.1 - - [10/Oct/2023:13:55:37 -0700] "GET /api/bar HTTP/1.1" 200 1 "-" "ua"`
+
+	// Interleave partial lines on two streams; each stream's buffer must
+	// stay independent of the other's.
+	if _, ok, err := p.ParseLine("2023-10-10T20:55:36Z stdout P " + stdoutLine[:10]); err != nil || ok {
+		t.Fatalf("stdout partial: ok=%v err=%v", ok, err)
+	}
+	if _, ok, err := p.ParseLine("2023-10-10T20:55:37Z stderr P " + stderrLine[:10]); err != nil || ok {
+		t.Fatalf("stderr partial: ok=%v err=%v", ok, err)
+	}
+
+	entry, ok, err := p.ParseLine("2023-10-10T20:55:36Z stdout F " + stdoutLine[10:])
+	if err != nil || !ok {
+		t.Fatalf("stdout final: ok=%v err=%v", ok, err)
+	}
+	if entry.URL != "/api/foo" {
+		t.Errorf("stdout reassembled URL = %q, want /api/foo", entry.URL)
+	}
+
+	entry, ok, err = p.ParseLine("2023-10-10T20:55:37Z stderr F " + stderrLine[10:])
+	if err != nil || !ok {
+		t.Fatalf("stderr final: ok=%v err=%v", ok, err)
+	}
+	if entry.URL != "/api/bar" {
+		t.Errorf("stderr reassembled URL = %q, want /api/bar", entry.URL)
+	}
+}
+
+func TestRegexParser_ParseLine(t *testing.T) {
+	p, err := NewRegexParser(`^(?P<method>\S+) (?P<uri>\S+)$`, "")
+	if err != nil {
+		t.Fatalf("NewRegexParser: %v", err)
+	}
+
+	entry, ok, err := p.ParseLine("GET /api/foo")
+	if err != nil {
+		t.Fatalf("ParseLine returned error: %v", err)
+	}
+	if !ok {
+		t.Fatalf("ParseLine returned ok=false for a matching line")
+	}
+	if entry.Method != "GET" || entry.URL != "/api/foo" {
+		t.Errorf("method/uri = %q %q, want GET /api/foo", entry.Method, entry.URL)
+	}
+	if entry.Proto != "HTTP/1.1" {
+		t.Errorf("Proto = %q, want default HTTP/1.1", entry.Proto)
+	}
+}
@@ -0,0 +1,195 @@
+package logfmt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nginxVarPattern matches $variable and $http_header_name tokens in an
+// nginx log_format directive.
+var nginxVarPattern = regexp.MustCompile(`\$([a-zA-Z_][a-zA-Z0-9_]*)`)
+
+// nginxFieldPattern is the regex fragment substituted for each known
+// variable. Variables without a specific fragment fall back to a generic
+// non-whitespace matcher, except $http_* and $sent_http_* header variables,
+// which match up to the next double quote.
+var nginxFieldPattern = map[string]string{
+	"time_local":             `[^\]]+`,
+	"time_iso8601":           `\S+`,
+	"request":                `[^"]*`,
+	"request_method":         `\S+`,
+	"request_uri":            `\S+`,
+	"status":                 `\d+`,
+	"body_bytes_sent":        `\d+`,
+	"request_time":           `\S+`,
+	"upstream_response_time": `\S+`,
+	"host":                   `\S+`,
+	"remote_addr":            `\S+`,
+	"request_body":           `[^"]*`,
+}
+
+// nginxTemplateParser parses access logs produced by a user-supplied nginx
+// log_format string, converting it to a regular expression once at
+// construction time.
+type nginxTemplateParser struct {
+	re *regexp.Regexp
+}
+
+// NewNginxTemplateParser compiles an nginx log_format directive (the
+// literal text configured via `log_format name '...'`, $variables and all)
+// into a LineParser.
+func NewNginxTemplateParser(template string) (LineParser, error) {
+	if template == "" {
+		return nil, fmt.Errorf("nginx: --nginx-template is required for --format=nginx")
+	}
+
+	var pattern strings.Builder
+	pattern.WriteByte('^')
+	last := 0
+	for _, loc := range nginxVarPattern.FindAllStringSubmatchIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		name := template[loc[2]:loc[3]]
+		frag, ok := nginxFieldPattern[name]
+		if !ok {
+			// Any $http_* (request header) or $sent_http_* (response
+			// header) variable is a header capture; quote-delimited is the
+			// safer default since that's how nginx log_format directives
+			// conventionally quote header values.
+			switch {
+			case strings.HasPrefix(name, "http_"), strings.HasPrefix(name, "sent_http_"):
+				frag = `[^"]*`
+			default:
+				frag = `\S+`
+			}
+		}
+		pattern.WriteString(fmt.Sprintf("(?P<%s>%s)", sanitizeGroupName(name), frag))
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("nginx: compiling template: %w", err)
+	}
+	return &nginxTemplateParser{re: re}, nil
+}
+
+// sanitizeGroupName makes an nginx variable name safe as a Go regexp named
+// group by replacing "-" with "_". Go accepts the word-character names that
+// nginxVarPattern matches, repeated names included, so they are otherwise
+// kept verbatim.
+func sanitizeGroupName(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// canonicalHeaderName turns the suffix of an nginx $http_* variable (e.g.
+// "user_agent", "x_forwarded_for") into the HTTP header name it represents
+// ("User-Agent", "X-Forwarded-For").
+func canonicalHeaderName(nginxSuffix string) string {
+	parts := strings.Split(nginxSuffix, "_")
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "-")
+}
+
+func (p *nginxTemplateParser) Name() string { return "nginx" }
+
+func (p *nginxTemplateParser) ParseLine(line string) (Entry, bool, error) {
+	m := p.re.FindStringSubmatch(line)
+	if m == nil {
+		return Entry{}, false, fmt.Errorf("nginx: line does not match configured template")
+	}
+	groups := namedGroups(p.re, m)
+
+	entry := Entry{
+		Proto:      "HTTP/1.1",
+		RemoteAddr: groups["remote_addr"],
+		Headers:    map[string]string{},
+	}
+
+	if req := groups["request"]; req != "" {
+		parts := strings.SplitN(req, " ", 3)
+		if len(parts) >= 2 {
+			entry.Method, entry.URL = parts[0], parts[1]
+		}
+		if len(parts) == 3 {
+			entry.Proto = parts[2]
+		}
+	}
+	if m := groups["request_method"]; m != "" {
+		entry.Method = m
+	}
+	if u := groups["request_uri"]; u != "" {
+		entry.URL = u
+	}
+	if host := groups["host"]; host != "" {
+		entry.Headers["Host"] = host
+	}
+	// Any $http_* variable in the template (http_host, http_referer,
+	// http_user_agent, http_cookie, http_content_type, ...) is a captured
+	// request header; recover it generically rather than special-casing a
+	// fixed allowlist, the way nginx itself treats $http_* variables.
+	for name, val := range groups {
+		if val == "" || val == "-" || !strings.HasPrefix(name, "http_") {
+			continue
+		}
+		entry.Headers[canonicalHeaderName(strings.TrimPrefix(name, "http_"))] = val
+	}
+	if body := groups["request_body"]; body != "" && body != "-" {
+		entry.Body = []byte(body)
+	}
+
+	if status := groups["status"]; status != "" {
+		if n, err := strconv.Atoi(status); err == nil {
+			entry.Status = n
+		}
+	}
+	if size := groups["body_bytes_sent"]; size != "" && size != "-" {
+		if n, err := strconv.ParseInt(size, 10, 64); err == nil {
+			entry.ResponseSize = n
+		}
+	}
+	if rt := groups["request_time"]; rt != "" {
+		if d, err := parseSeconds(rt); err == nil {
+			entry.Latency = d
+		}
+	} else if rt := groups["upstream_response_time"]; rt != "" {
+		if d, err := parseSeconds(rt); err == nil {
+			entry.Latency = d
+		}
+	}
+
+	if raw := groups["time_local"]; raw != "" {
+		ts, err := time.Parse(apacheTimeLayout, raw)
+		if err != nil {
+			return Entry{}, false, fmt.Errorf("nginx: parsing time_local: %w", err)
+		}
+		entry.Timestamp = ts
+	} else if raw := groups["time_iso8601"]; raw != "" {
+		ts, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return Entry{}, false, fmt.Errorf("nginx: parsing time_iso8601: %w", err)
+		}
+		entry.Timestamp = ts
+	}
+
+	return entry, true, nil
+}
+
+// parseSeconds parses an nginx $request_time-style value ("0.123") into a
+// time.Duration, used by callers that need the latency rather than a raw
+// string.
+func parseSeconds(s string) (time.Duration, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(f * float64(time.Second)), nil
+}
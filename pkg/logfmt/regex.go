@@ -0,0 +1,65 @@
+package logfmt
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// regexParser is the fallback used for --format-regex: a user-supplied
+// expression with named capture groups matching Entry fields.
+type regexParser struct {
+	re         *regexp.Regexp
+	timeLayout string
+}
+
+// NewRegexParser compiles pattern into a LineParser. pattern must define at
+// least the "method" and "uri" named groups; "proto", "time", "host",
+// "referer" and "useragent" are used when present. timeLayout is the Go
+// reference layout used to parse the "time" group; it defaults to the
+// Apache layout if empty.
+func NewRegexParser(pattern, timeLayout string) (LineParser, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("format-regex: %w", err)
+	}
+	if timeLayout == "" {
+		timeLayout = apacheTimeLayout
+	}
+	return &regexParser{re: re, timeLayout: timeLayout}, nil
+}
+
+func (p *regexParser) Name() string { return "regex" }
+
+func (p *regexParser) ParseLine(line string) (Entry, bool, error) {
+	m := p.re.FindStringSubmatch(line)
+	if m == nil {
+		return Entry{}, false, fmt.Errorf("format-regex: line does not match pattern")
+	}
+	groups := namedGroups(p.re, m)
+
+	entry := Entry{
+		Method:     groups["method"],
+		URL:        groups["uri"],
+		Proto:      groups["proto"],
+		RemoteAddr: groups["host"],
+		Headers:    map[string]string{},
+	}
+	if entry.Proto == "" {
+		entry.Proto = "HTTP/1.1"
+	}
+	if referer, ok := groups["referer"]; ok && referer != "" && referer != "-" {
+		entry.Headers["Referer"] = referer
+	}
+	if ua, ok := groups["useragent"]; ok && ua != "" && ua != "-" {
+		entry.Headers["User-Agent"] = ua
+	}
+	if raw, ok := groups["time"]; ok && raw != "" {
+		ts, err := time.Parse(p.timeLayout, raw)
+		if err != nil {
+			return Entry{}, false, fmt.Errorf("format-regex: parsing timestamp: %w", err)
+		}
+		entry.Timestamp = ts
+	}
+	return entry, true, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ORCID/log-to-gor/pkg/logfmt"
+)
+
+// flagTimeLayouts are tried in order when parsing --since/--until/--start-at.
+var flagTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05"}
+
+// parseFlagTime parses a --since/--until/--start-at value, accepting either
+// RFC3339 or the bare "2006-01-02T15:04:05" local-time form.
+func parseFlagTime(s string) (time.Time, error) {
+	var firstErr error
+	for _, layout := range flagTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return time.Time{}, fmt.Errorf("parsing time %q: %w", s, firstErr)
+}
+
+// timeWindow drops entries outside [Since, Until]. A zero value for either
+// bound means that side is unbounded.
+type timeWindow struct {
+	Since time.Time
+	Until time.Time
+}
+
+// Contains reports whether ts falls within the window.
+func (w timeWindow) Contains(ts time.Time) bool {
+	if !w.Since.IsZero() && ts.Before(w.Since) {
+		return false
+	}
+	if !w.Until.IsZero() && ts.After(w.Until) {
+		return false
+	}
+	return true
+}
+
+// timestampRewriter implements --speedup and --relative-time/--start-at by
+// rebasing each entry's timestamp relative to the first one it sees.
+type timestampRewriter struct {
+	speedup float64
+	base    time.Time // rebase target; zero means keep original timestamps
+	first   time.Time
+	started bool
+}
+
+// newTimestampRewriter builds a rewriter. speedup <= 0 means "no speed
+// change"; a zero base means "don't rebase the start time".
+func newTimestampRewriter(speedup float64, base time.Time) *timestampRewriter {
+	return &timestampRewriter{speedup: speedup, base: base}
+}
+
+// Rewrite returns the (possibly rebased and speed-scaled) timestamp to emit
+// for the next entry, which must be called in log order.
+func (r *timestampRewriter) Rewrite(ts time.Time) time.Time {
+	if !r.started {
+		r.started = true
+		r.first = ts
+		if r.base.IsZero() {
+			return ts
+		}
+		return r.base
+	}
+
+	gap := ts.Sub(r.first)
+	if r.speedup > 0 {
+		gap = time.Duration(float64(gap) / r.speedup)
+	}
+	base := r.base
+	if base.IsZero() {
+		base = r.first
+	}
+	return base.Add(gap)
+}
+
+// seekSinceOffset scans f backward in chunks to find the byte offset of the
+// first line at or after since, avoiding a full forward scan of logs that
+// are mostly older than the requested window. It assumes, like goreplay
+// itself, that log lines are in non-decreasing time order. Lines that fail
+// to parse or carry no timestamp are skipped; if no line before since is
+// found it returns offset 0 (scan everything). The returned offset is that
+// of the newline ending the last line before since, so a reader starting
+// there sees an empty first line.
+func seekSinceOffset(f *os.File, p logfmt.LineParser, since time.Time) (int64, error) {
+	const chunkSize = 64 * 1024
+
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	pos := size
+	tail := "" // carry-over fragment from the start of the previously read chunk
+	buf := make([]byte, chunkSize)
+
+	for pos > 0 {
+		readSize := int64(chunkSize)
+		if readSize > pos {
+			readSize = pos
+		}
+		start := pos - readSize
+		if _, err := f.Seek(start, io.SeekStart); err != nil {
+			return 0, err
+		}
+		n, err := io.ReadFull(f, buf[:readSize])
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+		chunk := string(buf[:n]) + tail
+
+		lines := strings.Split(chunk, "\n")
+		// lines[0] may be a partial line continued from an earlier (earlier
+		// in the file, i.e. not yet read) chunk; carry it over.
+		tail = lines[0]
+		complete := lines[1:]
+
+		for i := len(complete) - 1; i >= 0; i-- {
+			line := complete[i]
+			if line == "" {
+				continue
+			}
+			entry, ok, err := p.ParseLine(line)
+			if err != nil || !ok || entry.Timestamp.IsZero() {
+				continue
+			}
+			if entry.Timestamp.Before(since) {
+				// Offset of the newline ending this line, one byte before
+				// the line right after it.
+				offset := start + int64(len(tail))
+				for _, l := range complete[:i+1] {
+					offset += int64(len(l)) + 1
+				}
+				return offset, nil
+			}
+		}
+
+		pos = start
+	}
+	return 0, nil
+}
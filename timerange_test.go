@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ORCID/log-to-gor/pkg/logfmt"
+)
+
+func mustParseRFC3339(t *testing.T, s string) time.Time {
+	t.Helper()
+	ts, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", s, err)
+	}
+	return ts
+}
+
+func TestTimeWindow_Contains(t *testing.T) {
+	since := mustParseRFC3339(t, "2023-10-10T13:00:00Z")
+	until := mustParseRFC3339(t, "2023-10-10T15:00:00Z")
+
+	cases := []struct {
+		name string
+		w    timeWindow
+		ts   time.Time
+		want bool
+	}{
+		{"unbounded", timeWindow{}, mustParseRFC3339(t, "2000-01-01T00:00:00Z"), true},
+		{"before since", timeWindow{Since: since}, mustParseRFC3339(t, "2023-10-10T12:59:59Z"), false},
+		{"at since", timeWindow{Since: since}, since, true},
+		{"after until", timeWindow{Until: until}, mustParseRFC3339(t, "2023-10-10T15:00:01Z"), false},
+		{"at until", timeWindow{Until: until}, until, true},
+		{"inside both", timeWindow{Since: since, Until: until}, mustParseRFC3339(t, "2023-10-10T14:00:00Z"), true},
+	}
+	for _, c := range cases {
+		if got := c.w.Contains(c.ts); got != c.want {
+			t.Errorf("%s: Contains(%v) = %v, want %v", c.name, c.ts, got, c.want)
+		}
+	}
+}
+
+func TestTimestampRewriter_Rewrite_SpeedupOnly(t *testing.T) {
+	first := mustParseRFC3339(t, "2023-10-10T13:00:00Z")
+	second := first.Add(10 * time.Second)
+	r := newTimestampRewriter(2, time.Time{})
+
+	if got := r.Rewrite(first); !got.Equal(first) {
+		t.Errorf("first call = %v, want unchanged %v", got, first)
+	}
+	want := first.Add(5 * time.Second) // 10s gap / 2x speedup
+	if got := r.Rewrite(second); !got.Equal(want) {
+		t.Errorf("second call = %v, want %v", got, want)
+	}
+}
+
+func TestTimestampRewriter_Rewrite_RebaseOnly(t *testing.T) {
+	first := mustParseRFC3339(t, "2023-10-10T13:00:00Z")
+	second := first.Add(10 * time.Second)
+	base := mustParseRFC3339(t, "2024-01-01T00:00:00Z")
+	r := newTimestampRewriter(0, base)
+
+	if got := r.Rewrite(first); !got.Equal(base) {
+		t.Errorf("first call = %v, want rebased %v", got, base)
+	}
+	want := base.Add(10 * time.Second)
+	if got := r.Rewrite(second); !got.Equal(want) {
+		t.Errorf("second call = %v, want %v", got, want)
+	}
+}
+
+// scanFromOffset mimics how log-to-gor.go consumes the fast-path offset:
+// Seek then read with the same line scanner processLogs uses. A seek that
+// lands exactly on the preceding line's newline is fine here — the
+// scanner's first token is just an empty line, which callers already skip.
+func scanFromOffset(t *testing.T, f *os.File, offset int64) []string {
+	t.Helper()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if text := scanner.Text(); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scanning: %v", err)
+	}
+	return lines
+}
+
+func TestSeekSinceOffset(t *testing.T) {
+	lines := []string{
+		`127.0.0.1 - - [10/Oct/2023:13:00:00 -0700] "GET /a HTTP/1.1" 200 1 "-" "ua"`,
+		`127.0.0.1 - - [10/Oct/2023:13:00:10 -0700] "GET /b HTTP/1.1" 200 1 "-" "ua"`,
+		`127.0.0.1 - - [10/Oct/2023:13:00:20 -0700] "GET /c HTTP/1.1" 200 1 "-" "ua"`,
+	}
+	content := lines[0] + "\n" + lines[1] + "\n" + lines[2] + "\n"
+
+	f, err := os.CreateTemp(t.TempDir(), "seek-since-*.log")
+	if err != nil {
+		t.Fatalf("CreateTemp: %v", err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+
+	since := mustParseRFC3339(t, "2023-10-10T20:00:15Z") // between lines[1] and lines[2], UTC == -0700's 13:00:15
+	offset, err := seekSinceOffset(f, logfmt.NewApacheCombinedParser(), since)
+	if err != nil {
+		t.Fatalf("seekSinceOffset: %v", err)
+	}
+
+	got := scanFromOffset(t, f, offset)
+	want := []string{lines[2]}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("lines scanned from offset %d = %v, want %v", offset, got, want)
+	}
+}
+
+func TestSeekSinceOffset_BeforeAllLines(t *testing.T) {
+	content := `127.0.0.1 - - [10/Oct/2023:13:00:00 -0700] "GET /a HTTP/1.1" 200 1 "-" "ua"` + "\n"
+
+	f, err := os.CreateTemp(t.TempDir(), "seek-since-*.log")
+	if err != nil {
+		t.Fatalf("CreateTemp: %v", err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+
+	since := mustParseRFC3339(t, "2000-01-01T00:00:00Z")
+	offset, err := seekSinceOffset(f, logfmt.NewApacheCombinedParser(), since)
+	if err != nil {
+		t.Fatalf("seekSinceOffset: %v", err)
+	}
+	if offset != 0 {
+		t.Errorf("offset = %d, want 0 (since predates every line)", offset)
+	}
+}